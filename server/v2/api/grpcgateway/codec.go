@@ -0,0 +1,263 @@
+package grpcgateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/cosmos/gogoproto/jsonpb"
+	gogoproto "github.com/cosmos/gogoproto/proto"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	ContentTypeJSON        = "application/json"
+	ContentTypeProtobuf    = "application/x-protobuf"
+	contentTypeProtobufAlt = "application/protobuf"
+	ContentTypeGRPCWeb     = "application/grpc-web"
+	ContentTypeGRPCWebText = "application/grpc-web-text"
+
+	grpcWebTrailerFlag byte = 0x80
+)
+
+// decodeRequest creates a message from the matched Route, populating it from the request
+// body, URI wildcards (match.Params) and any unbound query parameters. The body is decoded
+// according to the request's Content-Type: application/json (the default) uses jsonpb,
+// while application/x-protobuf and application/protobuf use proto.Unmarshal.
+func decodeRequest(match *URIMatch, r *http.Request) (gogoproto.Message, error) {
+	msg, err := newMessage(match.Route.QueryInputName)
+	if err != nil {
+		return nil, err
+	}
+
+	defer r.Body.Close()
+	limitedReader := io.LimitReader(r.Body, MaxBodySize)
+	unmarshal := bodyUnmarshalerFor(r.Header.Get("Content-Type"))
+
+	switch match.Route.Body {
+	case "":
+		// no body to parse
+	case "*":
+		if err := unmarshal(limitedReader, msg); err != nil {
+			return nil, fmt.Errorf("error parsing body: %w", err)
+		}
+	default:
+		field, err := bodyField(msg, match.Route.Body)
+		if err != nil {
+			return nil, err
+		}
+		if err := unmarshal(limitedReader, field); err != nil {
+			return nil, fmt.Errorf("error parsing body into field %q: %w", match.Route.Body, err)
+		}
+	}
+
+	bound := make(map[string]bool, len(match.Params)+1)
+	for name := range match.Params {
+		bound[name] = true
+	}
+
+	if match.HasParams() {
+		if err := populateFromParams(msg, match.Params); err != nil {
+			return nil, err
+		}
+	}
+
+	// body: "*" already consumed the whole message; anything else leaves the remaining
+	// fields open to query-string population.
+	if match.Route.Body != "*" {
+		if match.Route.Body != "" {
+			bound[match.Route.Body] = true
+		}
+		if err := populateFromQuery(msg, r.URL.Query(), bound); err != nil {
+			return nil, err
+		}
+	}
+
+	return msg, nil
+}
+
+// bodyUnmarshalerFor returns the body-unmarshaling function matching contentType, defaulting
+// to jsonpb when contentType isn't a recognized protobuf media type.
+func bodyUnmarshalerFor(contentType string) func(io.Reader, gogoproto.Message) error {
+	if isProtobufMediaType(contentType) {
+		return func(r io.Reader, msg gogoproto.Message) error {
+			b, err := io.ReadAll(r)
+			if err != nil {
+				return err
+			}
+			return gogoproto.Unmarshal(b, msg)
+		}
+	}
+	return jsonpb.Unmarshal
+}
+
+// isProtobufMediaType reports whether contentType names the binary protobuf media type.
+func isProtobufMediaType(contentType string) bool {
+	mediaType := mediaTypeOf(contentType)
+	return mediaType == ContentTypeProtobuf || mediaType == contentTypeProtobufAlt
+}
+
+// mediaTypeOf strips any ";charset=..." style parameters and trims whitespace/case.
+func mediaTypeOf(contentType string) string {
+	return strings.ToLower(strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0]))
+}
+
+// UnaryInvoker invokes the rpc method whose request type is named by methodName with req and
+// returns its response message. Handler passes the matched Route's QueryInputName, the fully
+// qualified name of the proto input type, as methodName.
+type UnaryInvoker func(ctx context.Context, methodName string, req gogoproto.Message) (gogoproto.Message, error)
+
+// Handler serves unary routes matched by a Router. It decodes the request body according to
+// its Content-Type and encodes the response according to the request's Accept header, so the
+// same route table can serve JSON, native protobuf and gRPC-Web clients.
+type Handler struct {
+	router *Router
+	invoke UnaryInvoker
+}
+
+// NewHandler returns a Handler that resolves routes through router and invokes matched rpc
+// methods through invoke.
+func NewHandler(router *Router, invoke UnaryInvoker) *Handler {
+	return &Handler{router: router, invoke: invoke}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	match := h.router.Match(r.Method, r.URL.Path)
+	if match == nil || match.Route.IsStreaming {
+		http.NotFound(w, r)
+		return
+	}
+
+	req, err := decodeRequest(match, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.invoke(r.Context(), match.Route.QueryInputName, req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := encodeResponse(w, r, match.Route, resp); err != nil {
+		writeError(w, r, err)
+	}
+}
+
+// writeError reports err to the client in the format negotiated from r's Accept header: a
+// trailer-only gRPC-Web / gRPC-Web-text response carrying the real grpc-status when Accept
+// names one of those media types, otherwise a plain-text 500, matching how the streaming path
+// already frames errors for its own protocols.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch mediaTypeOf(r.Header.Get("Accept")) {
+	case ContentTypeGRPCWebText:
+		_ = writeGRPCWeb(w, nil, err, true)
+	case ContentTypeGRPCWeb:
+		_ = writeGRPCWeb(w, nil, err, false)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// encodeResponse renders the part of msg selected by route.ResponseBody onto w in the media
+// type negotiated from r's Accept header: application/json (default), application/x-protobuf
+// / application/protobuf, or application/grpc-web / application/grpc-web-text.
+func encodeResponse(w http.ResponseWriter, r *http.Request, route *Route, msg gogoproto.Message) error {
+	msg, err := selectResponseBody(route, msg)
+	if err != nil {
+		return err
+	}
+
+	accept := mediaTypeOf(r.Header.Get("Accept"))
+
+	switch {
+	case accept == ContentTypeGRPCWebText:
+		return writeGRPCWeb(w, msg, nil, true)
+	case accept == ContentTypeGRPCWeb:
+		return writeGRPCWeb(w, msg, nil, false)
+	case accept == ContentTypeProtobuf || accept == contentTypeProtobufAlt:
+		w.Header().Set("Content-Type", ContentTypeProtobuf)
+		b, err := gogoproto.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	default:
+		w.Header().Set("Content-Type", ContentTypeJSON)
+		return (&jsonpb.Marshaler{}).Marshal(w, msg)
+	}
+}
+
+// selectResponseBody returns the part of msg named by route's ResponseBody selector: the
+// whole message when the selector is empty, or the named field's message otherwise.
+func selectResponseBody(route *Route, msg gogoproto.Message) (gogoproto.Message, error) {
+	if route.ResponseBody == "" {
+		return msg, nil
+	}
+	return responseField(msg, route.ResponseBody)
+}
+
+// writeGRPCWeb renders msg as a gRPC-Web framed response: a 5-byte length-prefixed data frame
+// holding the marshaled message, followed by a trailing HEADERS frame carrying
+// grpc-status/grpc-message derived from respErr. When respErr is non-nil, msg is ignored and
+// only the trailer frame is written, so a failed rpc still yields a response gRPC-Web clients
+// can parse. When text is true, the whole framed payload is base64-encoded, matching the
+// application/grpc-web-text wire format.
+func writeGRPCWeb(w http.ResponseWriter, msg gogoproto.Message, respErr error, text bool) error {
+	contentType := ContentTypeGRPCWeb
+	if text {
+		contentType = ContentTypeGRPCWebText
+	}
+	w.Header().Set("Content-Type", contentType)
+
+	var buf bytes.Buffer
+	if respErr == nil {
+		payload, err := gogoproto.Marshal(msg)
+		if err != nil {
+			respErr = err
+		} else if err := writeGRPCWebFrame(&buf, 0x00, payload); err != nil {
+			return err
+		}
+	}
+	if err := writeGRPCWebFrame(&buf, grpcWebTrailerFlag, grpcWebTrailer(respErr)); err != nil {
+		return err
+	}
+
+	out := buf.Bytes()
+	if text {
+		out = []byte(base64.StdEncoding.EncodeToString(out))
+	}
+
+	_, err := w.Write(out)
+	return err
+}
+
+// writeGRPCWebFrame writes a single gRPC-Web frame: a 1-byte flag, a 4-byte big-endian
+// length, and the payload.
+func writeGRPCWebFrame(w io.Writer, flag byte, payload []byte) error {
+	header := make([]byte, 5)
+	header[0] = flag
+	binary.BigEndian.PutUint32(header[1:], uint32(len(payload)))
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	_, err := w.Write(payload)
+	return err
+}
+
+// grpcWebTrailer renders the grpc-status/grpc-message trailer block for streamErr (nil means
+// a successful, OK status). The real gRPC code is extracted via status.Convert when streamErr
+// carries one, falling back to codes.Unknown for plain errors.
+func grpcWebTrailer(streamErr error) []byte {
+	st := status.Convert(streamErr)
+	return []byte(fmt.Sprintf("grpc-status: %d\r\ngrpc-message: %s\r\n", st.Code(), st.Message()))
+}
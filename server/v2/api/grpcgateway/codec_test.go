@@ -0,0 +1,225 @@
+package grpcgateway
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/cosmos/gogoproto/jsonpb"
+	gogoproto "github.com/cosmos/gogoproto/proto"
+)
+
+const fileDescriptorProtoName = "google.protobuf.FileDescriptorProto"
+
+// strPtr returns a pointer to s, matching the *string fields on generated proto messages.
+func strPtr(s string) *string { return &s }
+
+func TestDecodeRequestJSONBody(t *testing.T) {
+	route := &Route{QueryInputName: fileDescriptorProtoName, Body: "*"}
+	r := httptest.NewRequest(http.MethodPost, "/x", strings.NewReader(`{"name":"my.proto"}`))
+	r.Header.Set("Content-Type", ContentTypeJSON)
+
+	msg, err := decodeRequest(&URIMatch{Route: route}, r)
+	require.NoError(t, err)
+	require.Equal(t, "my.proto", msg.(*descriptorpb.FileDescriptorProto).GetName())
+}
+
+func TestDecodeRequestProtobufBody(t *testing.T) {
+	body, err := gogoproto.Marshal(&descriptorpb.FileDescriptorProto{Name: strPtr("my.proto")})
+	require.NoError(t, err)
+
+	route := &Route{QueryInputName: fileDescriptorProtoName, Body: "*"}
+	r := httptest.NewRequest(http.MethodPost, "/x", strings.NewReader(string(body)))
+	r.Header.Set("Content-Type", ContentTypeProtobuf)
+
+	msg, err := decodeRequest(&URIMatch{Route: route}, r)
+	require.NoError(t, err)
+	require.Equal(t, "my.proto", msg.(*descriptorpb.FileDescriptorProto).GetName())
+}
+
+func TestDecodeRequestBodyFieldSelectorLeavesOtherFieldsToParams(t *testing.T) {
+	route := &Route{QueryInputName: fileDescriptorProtoName, Body: "options", Pattern: "/x/{name}"}
+	r := httptest.NewRequest(http.MethodPost, "/x/my.proto", strings.NewReader(`{"java_package":"com.example"}`))
+	r.Header.Set("Content-Type", ContentTypeJSON)
+
+	match := &URIMatch{Route: route, Params: map[string]string{"name": "my.proto"}}
+	msg, err := decodeRequest(match, r)
+	require.NoError(t, err)
+
+	fd := msg.(*descriptorpb.FileDescriptorProto)
+	require.Equal(t, "my.proto", fd.GetName())
+	require.Equal(t, "com.example", fd.GetOptions().GetJavaPackage())
+}
+
+func TestEncodeResponsePerContentType(t *testing.T) {
+	resp := &descriptorpb.FileDescriptorProto{Name: strPtr("my.proto")}
+	route := &Route{}
+
+	cases := []struct {
+		name        string
+		accept      string
+		contentType string
+	}{
+		{"default json", "", ContentTypeJSON},
+		{"json", ContentTypeJSON, ContentTypeJSON},
+		{"protobuf", ContentTypeProtobuf, ContentTypeProtobuf},
+		{"protobuf alt", contentTypeProtobufAlt, ContentTypeProtobuf},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/x", nil)
+			r.Header.Set("Accept", tc.accept)
+
+			require.NoError(t, encodeResponse(w, r, route, resp))
+			require.Equal(t, tc.contentType, w.Header().Get("Content-Type"))
+
+			got := &descriptorpb.FileDescriptorProto{}
+			if tc.contentType == ContentTypeJSON {
+				require.NoError(t, jsonpb.Unmarshal(strings.NewReader(w.Body.String()), got))
+			} else {
+				require.NoError(t, gogoproto.Unmarshal(w.Body.Bytes(), got))
+			}
+			require.Equal(t, "my.proto", got.GetName())
+		})
+	}
+}
+
+func TestEncodeResponseGRPCWebFramesTheMessage(t *testing.T) {
+	resp := &descriptorpb.FileDescriptorProto{Name: strPtr("my.proto")}
+	route := &Route{}
+
+	for _, tc := range []struct {
+		accept string
+		text   bool
+	}{
+		{ContentTypeGRPCWeb, false},
+		{ContentTypeGRPCWebText, true},
+	} {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/x", nil)
+		r.Header.Set("Accept", tc.accept)
+
+		require.NoError(t, encodeResponse(w, r, route, resp))
+
+		frames := decodeGRPCWebFrames(t, w.Body.Bytes(), tc.text)
+		require.Len(t, frames, 2)
+		require.Equal(t, byte(0x00), frames[0].flag)
+		got := &descriptorpb.FileDescriptorProto{}
+		require.NoError(t, gogoproto.Unmarshal(frames[0].payload, got))
+		require.Equal(t, "my.proto", got.GetName())
+
+		require.Equal(t, grpcWebTrailerFlag, frames[1].flag)
+		require.Contains(t, string(frames[1].payload), "grpc-status: 0")
+	}
+}
+
+func TestEncodeResponseHonorsResponseBodySelector(t *testing.T) {
+	resp := &descriptorpb.FileDescriptorProto{
+		Name:    strPtr("my.proto"),
+		Options: &descriptorpb.FileOptions{JavaPackage: strPtr("com.example")},
+	}
+	route := &Route{ResponseBody: "options"}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/x", nil)
+
+	require.NoError(t, encodeResponse(w, r, route, resp))
+
+	got := &descriptorpb.FileOptions{}
+	require.NoError(t, jsonpb.Unmarshal(strings.NewReader(w.Body.String()), got))
+	require.Equal(t, "com.example", got.GetJavaPackage())
+}
+
+func TestWriteErrorGRPCWebFramesTheRealStatus(t *testing.T) {
+	rpcErr := status.Error(codes.NotFound, "not found")
+
+	for _, tc := range []struct {
+		accept string
+		text   bool
+	}{
+		{ContentTypeGRPCWeb, false},
+		{ContentTypeGRPCWebText, true},
+	} {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/x", nil)
+		r.Header.Set("Accept", tc.accept)
+
+		writeError(w, r, rpcErr)
+
+		require.Equal(t, http.StatusOK, w.Code, "gRPC-Web errors are trailer-only, not an HTTP error status")
+		frames := decodeGRPCWebFrames(t, w.Body.Bytes(), tc.text)
+		require.Len(t, frames, 1, "a failed rpc must not emit a data frame")
+		require.Equal(t, grpcWebTrailerFlag, frames[0].flag)
+		require.Contains(t, string(frames[0].payload), "grpc-status: 5")
+		require.Contains(t, string(frames[0].payload), "not found")
+	}
+}
+
+func TestWriteErrorPlainTextForJSONClients(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/x", nil)
+
+	writeError(w, r, status.Error(codes.NotFound, "not found"))
+
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.Contains(t, w.Body.String(), "not found")
+}
+
+func TestServeHTTPInvokeErrorGRPCWeb(t *testing.T) {
+	rt := NewRouter()
+	rt.Register(&Route{Method: http.MethodGet, Pattern: "/x", QueryInputName: fileDescriptorProtoName})
+
+	h := NewHandler(rt, func(ctx context.Context, methodName string, req gogoproto.Message) (gogoproto.Message, error) {
+		return nil, status.Error(codes.NotFound, "not found")
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/x", nil)
+	r.Header.Set("Accept", ContentTypeGRPCWeb)
+
+	h.ServeHTTP(w, r)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	frames := decodeGRPCWebFrames(t, w.Body.Bytes(), false)
+	require.Len(t, frames, 1)
+	require.Contains(t, string(frames[0].payload), "grpc-status: 5")
+}
+
+type grpcWebFrame struct {
+	flag    byte
+	payload []byte
+}
+
+// decodeGRPCWebFrames splits a gRPC-Web response body into its constituent frames, undoing the
+// base64 encoding used by the "-text" variant first.
+func decodeGRPCWebFrames(t *testing.T, body []byte, text bool) []grpcWebFrame {
+	t.Helper()
+
+	if text {
+		decoded, err := base64.StdEncoding.DecodeString(string(body))
+		require.NoError(t, err)
+		body = decoded
+	}
+
+	var frames []grpcWebFrame
+	for len(body) > 0 {
+		require.GreaterOrEqual(t, len(body), 5)
+		flag := body[0]
+		length := binary.BigEndian.Uint32(body[1:5])
+		payload := body[5 : 5+length]
+		frames = append(frames, grpcWebFrame{flag: flag, payload: payload})
+		body = body[5+length:]
+	}
+	return frames
+}
@@ -0,0 +1,122 @@
+package grpcgateway
+
+import (
+	"fmt"
+	"net/http"
+
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// RegisterQueryServices walks the file descriptors of each named query service, parses the
+// google.api.http option on every rpc method (including any additional_bindings), and
+// registers one Route per binding into rt. This builds the same route table a
+// grpc-gateway-generated stub would expose, without requiring callers to hand-build a
+// pattern-to-input map.
+func RegisterQueryServices(rt *Router, serviceNames ...string) error {
+	for _, name := range serviceNames {
+		if err := registerServiceRoutes(rt, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// registerServiceRoutes registers the routes declared by a single proto service.
+func registerServiceRoutes(rt *Router, serviceName string) error {
+	desc, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(serviceName))
+	if err != nil {
+		return fmt.Errorf("service %q not found in the global proto registry: %w", serviceName, err)
+	}
+
+	svc, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return fmt.Errorf("%q is not a service", serviceName)
+	}
+
+	methods := svc.Methods()
+	for i := 0; i < methods.Len(); i++ {
+		method := methods.Get(i)
+		if method.IsStreamingClient() {
+			// client-streaming and bidi-streaming rpcs have no meaningful HTTP binding.
+			continue
+		}
+
+		rule := httpRule(method)
+		if rule == nil {
+			continue
+		}
+
+		for _, route := range routesFromRule(method, rule) {
+			rt.Register(route)
+		}
+	}
+
+	return nil
+}
+
+// httpRule extracts the google.api.http extension from a method's options, if present.
+func httpRule(method protoreflect.MethodDescriptor) *annotations.HttpRule {
+	opts, ok := method.Options().(*descriptorpb.MethodOptions)
+	if !ok || opts == nil {
+		return nil
+	}
+
+	rule, ok := proto.GetExtension(opts, annotations.E_Http).(*annotations.HttpRule)
+	if !ok || rule == nil {
+		return nil
+	}
+
+	return rule
+}
+
+// routesFromRule produces one Route for rule's primary binding plus one per entry in its
+// additional_bindings.
+func routesFromRule(method protoreflect.MethodDescriptor, rule *annotations.HttpRule) []*Route {
+	inputName := string(method.Input().FullName())
+	streaming := method.IsStreamingServer()
+
+	routes := make([]*Route, 0, 1+len(rule.GetAdditionalBindings()))
+	routes = append(routes, routeFromRule(inputName, streaming, rule))
+	for _, binding := range rule.GetAdditionalBindings() {
+		routes = append(routes, routeFromRule(inputName, streaming, binding))
+	}
+
+	return routes
+}
+
+// routeFromRule converts a single google.api.http binding into a Route.
+func routeFromRule(inputName string, streaming bool, rule *annotations.HttpRule) *Route {
+	verb, pattern := httpVerbAndPattern(rule)
+	return &Route{
+		Method:         verb,
+		Pattern:        pattern,
+		QueryInputName: inputName,
+		Body:           rule.GetBody(),
+		ResponseBody:   rule.GetResponseBody(),
+		IsStreaming:    streaming,
+	}
+}
+
+// httpVerbAndPattern extracts the HTTP verb and path template from a google.api.http binding.
+func httpVerbAndPattern(rule *annotations.HttpRule) (string, string) {
+	switch pattern := rule.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		return http.MethodGet, pattern.Get
+	case *annotations.HttpRule_Post:
+		return http.MethodPost, pattern.Post
+	case *annotations.HttpRule_Put:
+		return http.MethodPut, pattern.Put
+	case *annotations.HttpRule_Patch:
+		return http.MethodPatch, pattern.Patch
+	case *annotations.HttpRule_Delete:
+		return http.MethodDelete, pattern.Delete
+	case *annotations.HttpRule_Custom:
+		return pattern.Custom.GetKind(), pattern.Custom.GetPath()
+	default:
+		return "", ""
+	}
+}
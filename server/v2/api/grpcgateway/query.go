@@ -0,0 +1,120 @@
+package grpcgateway
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	gogoproto "github.com/cosmos/gogoproto/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// populateFromQuery fills msg's fields from URL query parameters that were not already bound
+// by the path or the request body. Nested messages are addressed with dotted field paths
+// (e.g. "page.limit=50"), and repeated fields accept the same key multiple times
+// (e.g. "ids=1&ids=2"). Field lookup and scalar coercion are driven by the message's protobuf
+// field descriptors, so enums accept either their name or their numeric value.
+func populateFromQuery(msg gogoproto.Message, values url.Values, bound map[string]bool) error {
+	reflectable, ok := msg.(interface{ ProtoReflect() protoreflect.Message })
+	if !ok {
+		return fmt.Errorf("message %T does not support protobuf reflection", msg)
+	}
+	reflectMsg := reflectable.ProtoReflect()
+
+	for key, raw := range values {
+		path := strings.Split(key, ".")
+		if bound[path[0]] {
+			continue
+		}
+
+		if err := setQueryField(reflectMsg, path, raw); err != nil {
+			return fmt.Errorf("error populating query field %q: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// setQueryField resolves the leading element of path against msg's field descriptors, either
+// recursing into a nested message or assigning raw to the resolved scalar/repeated field.
+func setQueryField(msg protoreflect.Message, path []string, raw []string) error {
+	fields := msg.Descriptor().Fields()
+	fd := fields.ByJSONName(path[0])
+	if fd == nil {
+		fd = fields.ByName(protoreflect.Name(path[0]))
+	}
+	if fd == nil {
+		// unknown query param, ignore it as grpc-gateway does.
+		return nil
+	}
+
+	if len(path) > 1 {
+		if fd.Message() == nil {
+			return fmt.Errorf("field %q is not a message", path[0])
+		}
+		return setQueryField(msg.Mutable(fd).Message(), path[1:], raw)
+	}
+
+	if fd.IsList() {
+		list := msg.Mutable(fd).List()
+		for _, r := range raw {
+			v, err := coerceScalar(fd, r)
+			if err != nil {
+				return err
+			}
+			list.Append(v)
+		}
+		return nil
+	}
+
+	v, err := coerceScalar(fd, raw[len(raw)-1])
+	if err != nil {
+		return err
+	}
+	msg.Set(fd, v)
+
+	return nil
+}
+
+// coerceScalar converts raw into a protoreflect.Value matching fd's kind.
+func coerceScalar(fd protoreflect.FieldDescriptor, raw string) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		b, err := strconv.ParseBool(raw)
+		return protoreflect.ValueOfBool(b), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		n, err := strconv.ParseInt(raw, 10, 32)
+		return protoreflect.ValueOfInt32(int32(n)), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		return protoreflect.ValueOfInt64(n), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		n, err := strconv.ParseUint(raw, 10, 32)
+		return protoreflect.ValueOfUint32(uint32(n)), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		n, err := strconv.ParseUint(raw, 10, 64)
+		return protoreflect.ValueOfUint64(n), err
+	case protoreflect.FloatKind:
+		f, err := strconv.ParseFloat(raw, 32)
+		return protoreflect.ValueOfFloat32(float32(f)), err
+	case protoreflect.DoubleKind:
+		f, err := strconv.ParseFloat(raw, 64)
+		return protoreflect.ValueOfFloat64(f), err
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(raw), nil
+	case protoreflect.BytesKind:
+		return protoreflect.ValueOfBytes([]byte(raw)), nil
+	case protoreflect.EnumKind:
+		if n, err := strconv.ParseInt(raw, 10, 32); err == nil {
+			return protoreflect.ValueOfEnum(protoreflect.EnumNumber(n)), nil
+		}
+		ev := fd.Enum().Values().ByName(protoreflect.Name(raw))
+		if ev == nil {
+			return protoreflect.Value{}, fmt.Errorf("unknown enum value %q for %s", raw, fd.Enum().FullName())
+		}
+		return protoreflect.ValueOfEnum(ev.Number()), nil
+	default:
+		return protoreflect.Value{}, fmt.Errorf("unsupported query field kind %s", fd.Kind())
+	}
+}
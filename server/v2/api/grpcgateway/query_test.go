@@ -0,0 +1,59 @@
+package grpcgateway
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// These tests exercise populateFromQuery against google.golang.org/protobuf's own generated
+// descriptor messages, which give us real protoreflect field descriptors (scalars, a repeated
+// scalar, a nested message and an enum) without depending on any service-specific proto types.
+
+func TestPopulateFromQueryScalarAndNestedDottedPath(t *testing.T) {
+	msg := &descriptorpb.FileDescriptorProto{}
+	values := url.Values{
+		"name":                 {"my.proto"},
+		"options.java_package": {"com.example"},
+	}
+
+	require.NoError(t, populateFromQuery(msg, values, nil))
+	require.Equal(t, "my.proto", msg.GetName())
+	require.Equal(t, "com.example", msg.GetOptions().GetJavaPackage())
+}
+
+func TestPopulateFromQueryRepeatedField(t *testing.T) {
+	msg := &descriptorpb.FileDescriptorProto{}
+
+	require.NoError(t, populateFromQuery(msg, url.Values{"public_dependency": {"1", "2", "3"}}, nil))
+	require.Equal(t, []int32{1, 2, 3}, msg.GetPublicDependency())
+}
+
+func TestPopulateFromQueryEnumByNameAndByNumber(t *testing.T) {
+	byName := &descriptorpb.FieldDescriptorProto{}
+	require.NoError(t, populateFromQuery(byName, url.Values{"label": {"LABEL_REPEATED"}}, nil))
+	require.Equal(t, descriptorpb.FieldDescriptorProto_LABEL_REPEATED, byName.GetLabel())
+
+	byNumber := &descriptorpb.FieldDescriptorProto{}
+	require.NoError(t, populateFromQuery(byNumber, url.Values{"label": {"2"}}, nil))
+	require.Equal(t, descriptorpb.FieldDescriptorProto_LABEL_REQUIRED, byNumber.GetLabel())
+
+	invalid := &descriptorpb.FieldDescriptorProto{}
+	require.Error(t, populateFromQuery(invalid, url.Values{"label": {"bogus"}}, nil))
+}
+
+func TestPopulateFromQuerySkipsBoundFields(t *testing.T) {
+	msg := &descriptorpb.FileDescriptorProto{}
+
+	err := populateFromQuery(msg, url.Values{"name": {"should-not-apply"}}, map[string]bool{"name": true})
+	require.NoError(t, err)
+	require.Empty(t, msg.GetName())
+}
+
+func TestPopulateFromQueryIgnoresUnknownParams(t *testing.T) {
+	msg := &descriptorpb.FileDescriptorProto{}
+
+	require.NoError(t, populateFromQuery(msg, url.Values{"does_not_exist": {"x"}}, nil))
+}
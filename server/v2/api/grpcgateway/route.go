@@ -0,0 +1,81 @@
+package grpcgateway
+
+import (
+	"strings"
+)
+
+// Route describes a single HTTP binding for a Msg/Query service method, mirroring the
+// semantics of a google.api.http annotation.
+type Route struct {
+	// Method is the HTTP verb this route responds to (GET, POST, PUT, PATCH, DELETE).
+	Method string
+
+	// Pattern is the raw URI pattern, e.g. "/cosmos/bank/v1beta1/balances/{address}".
+	Pattern string
+
+	// QueryInputName is the fully qualified name of the proto input type of the rpc method.
+	QueryInputName string
+
+	// Body selects which part of the request message is populated from the request body.
+	// An empty string means the body is not read, "*" means the whole message is populated
+	// from the body, and any other value names the field (in protobuf field-name form) that
+	// receives the body, with the remaining fields populated from URI wildcards.
+	Body string
+
+	// ResponseBody selects which field of the response message is rendered back to the
+	// caller. An empty string means the whole response message is rendered.
+	ResponseBody string
+
+	// IsStreaming reports whether the bound rpc method is server-streaming, in which case
+	// the route must be served through the StreamHandler rather than a unary handler.
+	IsStreaming bool
+}
+
+// Router owns the set of registered Routes, precompiled at registration time into a trie
+// bucketed per HTTP verb, and resolves an incoming (method, path) pair to the Route and
+// wildcard params that match it in O(depth), with no per-request regex compilation.
+type Router struct {
+	trees map[string]*trieNode
+}
+
+// NewRouter returns an empty Router.
+func NewRouter() *Router {
+	return &Router{trees: make(map[string]*trieNode)}
+}
+
+// Register compiles route's pattern once and inserts it into the trie for its HTTP method.
+func (rt *Router) Register(route *Route) {
+	root, ok := rt.trees[route.Method]
+	if !ok {
+		root = newTrieNode()
+		rt.trees[route.Method] = root
+	}
+	root.insert(tokenizePattern(route.Pattern), route)
+}
+
+// Match attempts to find a registered Route for the given HTTP method and path.
+// NOTE: if no match is found, nil is returned.
+func (rt *Router) Match(method, path string) *URIMatch {
+	root, ok := rt.trees[method]
+	if !ok {
+		return nil
+	}
+
+	path = strings.Trim(path, "/")
+	var segments []string
+	if path != "" {
+		segments = strings.Split(path, "/")
+	}
+
+	params := make(map[string]string)
+	route := root.match(segments, params)
+	if route == nil {
+		return nil
+	}
+
+	match := &URIMatch{Route: route}
+	if len(params) > 0 {
+		match.Params = params
+	}
+	return match
+}
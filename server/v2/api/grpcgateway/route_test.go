@@ -0,0 +1,62 @@
+package grpcgateway
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouterMatchPrefersMoreSpecificRoute(t *testing.T) {
+	rt := NewRouter()
+	allBalances := &Route{
+		Method:         http.MethodGet,
+		Pattern:        "/cosmos/bank/v1beta1/balances/{address}",
+		QueryInputName: "cosmos.bank.v1beta1.QueryAllBalancesRequest",
+	}
+	balanceByDenom := &Route{
+		Method:         http.MethodGet,
+		Pattern:        "/cosmos/bank/v1beta1/balances/{address}/by_denom",
+		QueryInputName: "cosmos.bank.v1beta1.QueryBalanceRequest",
+	}
+	rt.Register(allBalances)
+	rt.Register(balanceByDenom)
+
+	match := rt.Match(http.MethodGet, "/cosmos/bank/v1beta1/balances/cosmos1abc")
+	require.NotNil(t, match)
+	require.Same(t, allBalances, match.Route)
+	require.Equal(t, "cosmos1abc", match.Params["address"])
+
+	match = rt.Match(http.MethodGet, "/cosmos/bank/v1beta1/balances/cosmos1abc/by_denom")
+	require.NotNil(t, match)
+	require.Same(t, balanceByDenom, match.Route)
+	require.Equal(t, "cosmos1abc", match.Params["address"])
+}
+
+func TestRouterMatchIsBucketedPerMethod(t *testing.T) {
+	rt := NewRouter()
+	get := &Route{Method: http.MethodGet, Pattern: "/things/{id}"}
+	post := &Route{Method: http.MethodPost, Pattern: "/things/{id}"}
+	rt.Register(get)
+	rt.Register(post)
+
+	require.Same(t, get, rt.Match(http.MethodGet, "/things/1").Route)
+	require.Same(t, post, rt.Match(http.MethodPost, "/things/1").Route)
+	require.Nil(t, rt.Match(http.MethodDelete, "/things/1"))
+}
+
+func TestRouterMatchWithoutParams(t *testing.T) {
+	rt := NewRouter()
+	rt.Register(&Route{Method: http.MethodGet, Pattern: "/cosmos/base/tendermint/v1beta1/node_info"})
+
+	match := rt.Match(http.MethodGet, "/cosmos/base/tendermint/v1beta1/node_info")
+	require.NotNil(t, match)
+	require.False(t, match.HasParams())
+}
+
+func TestRouterMatchUnregisteredPath(t *testing.T) {
+	rt := NewRouter()
+	rt.Register(&Route{Method: http.MethodGet, Pattern: "/things/{id}"})
+
+	require.Nil(t, rt.Match(http.MethodGet, "/other"))
+}
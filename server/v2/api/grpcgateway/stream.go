@@ -0,0 +1,135 @@
+package grpcgateway
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/cosmos/gogoproto/jsonpb"
+	gogoproto "github.com/cosmos/gogoproto/proto"
+)
+
+// StreamInvoker opens a server-streaming call for the rpc method whose request type is named
+// by methodName (StreamHandler passes the matched Route's QueryInputName, the fully qualified
+// name of the proto input type), with req as the request message, and returns a recv func that yields one response message per
+// call, returning io.EOF once the stream ends normally. Implementations must stop the
+// upstream call when ctx is cancelled.
+type StreamInvoker func(ctx context.Context, methodName string, req gogoproto.Message) (recv func() (gogoproto.Message, error), err error)
+
+// StreamHandler serves server-streaming routes matched by a Router. It negotiates the
+// response framing from the Accept header: application/json (the default) emits a
+// newline-delimited JSON stream with chunked transfer encoding, and text/event-stream emits
+// Server-Sent Events suitable for a browser EventSource.
+type StreamHandler struct {
+	router *Router
+	invoke StreamInvoker
+}
+
+// NewStreamHandler returns a StreamHandler that resolves routes through router and opens
+// streams through invoke.
+func NewStreamHandler(router *Router, invoke StreamInvoker) *StreamHandler {
+	return &StreamHandler{router: router, invoke: invoke}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *StreamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	match := h.router.Match(r.Method, r.URL.Path)
+	if match == nil || !match.Route.IsStreaming {
+		http.NotFound(w, r)
+		return
+	}
+
+	req, err := decodeRequest(match, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	recv, err := h.invoke(ctx, match.Route.QueryInputName, req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	sse := acceptsEventStream(r.Header.Get("Accept"))
+	if sse {
+		w.Header().Set("Content-Type", "text/event-stream")
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.Header().Set("Transfer-Encoding", "chunked")
+	w.WriteHeader(http.StatusOK)
+
+	flusher, _ := w.(http.Flusher)
+	marshaler := &jsonpb.Marshaler{}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		msg, err := recv()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			writeFrame(w, sse, marshaler, nil, err)
+			if flusher != nil {
+				flusher.Flush()
+			}
+			return
+		}
+
+		msg, err = selectResponseBody(match.Route, msg)
+		if err != nil {
+			writeFrame(w, sse, marshaler, nil, err)
+			if flusher != nil {
+				flusher.Flush()
+			}
+			return
+		}
+
+		writeFrame(w, sse, marshaler, msg, nil)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
+
+// writeFrame renders a single stream item (a result or a terminal error) in the negotiated
+// framing.
+func writeFrame(w io.Writer, sse bool, marshaler *jsonpb.Marshaler, msg gogoproto.Message, streamErr error) {
+	var buf bytes.Buffer
+
+	switch {
+	case streamErr != nil:
+		fmt.Fprintf(&buf, `{"error":%q}`, streamErr.Error())
+	default:
+		buf.WriteString(`{"result":`)
+		if err := marshaler.Marshal(&buf, msg); err != nil {
+			buf.Reset()
+			fmt.Fprintf(&buf, `{"error":%q}`, err.Error())
+		} else {
+			buf.WriteString(`}`)
+		}
+	}
+
+	if sse {
+		fmt.Fprintf(w, "data: %s\n\n", buf.String())
+		return
+	}
+
+	buf.WriteByte('\n')
+	w.Write(buf.Bytes())
+}
+
+// acceptsEventStream reports whether the client's Accept header asks for Server-Sent Events.
+func acceptsEventStream(accept string) bool {
+	return strings.Contains(accept, "text/event-stream")
+}
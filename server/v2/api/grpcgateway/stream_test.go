@@ -0,0 +1,146 @@
+package grpcgateway
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	gogoproto "github.com/cosmos/gogoproto/proto"
+)
+
+// recvSequence returns a StreamInvoker recv func that yields items in order, then io.EOF.
+func recvSequence(items ...gogoproto.Message) func() (gogoproto.Message, error) {
+	i := 0
+	return func() (gogoproto.Message, error) {
+		if i >= len(items) {
+			return nil, io.EOF
+		}
+		item := items[i]
+		i++
+		return item, nil
+	}
+}
+
+func newStreamHandler(t *testing.T, route *Route, invoke StreamInvoker) *StreamHandler {
+	t.Helper()
+	rt := NewRouter()
+	route.Method = http.MethodGet
+	route.Pattern = "/x"
+	route.IsStreaming = true
+	rt.Register(route)
+	return NewStreamHandler(rt, invoke)
+}
+
+func TestStreamHandlerServeHTTPJSONFraming(t *testing.T) {
+	h := newStreamHandler(t, &Route{QueryInputName: fileDescriptorProtoName}, func(ctx context.Context, methodName string, req gogoproto.Message) (func() (gogoproto.Message, error), error) {
+		return recvSequence(
+			&descriptorpb.FileDescriptorProto{Name: strPtr("a.proto")},
+			&descriptorpb.FileDescriptorProto{Name: strPtr("b.proto")},
+		), nil
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/x", nil)
+
+	h.ServeHTTP(w, r)
+
+	require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	lines := splitNonEmptyLines(w.Body.String())
+	require.Len(t, lines, 2)
+	require.Contains(t, lines[0], `"result":`)
+	require.Contains(t, lines[0], "a.proto")
+	require.Contains(t, lines[1], "b.proto")
+}
+
+func TestStreamHandlerServeHTTPSSEFraming(t *testing.T) {
+	h := newStreamHandler(t, &Route{QueryInputName: fileDescriptorProtoName}, func(ctx context.Context, methodName string, req gogoproto.Message) (func() (gogoproto.Message, error), error) {
+		return recvSequence(&descriptorpb.FileDescriptorProto{Name: strPtr("a.proto")}), nil
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/x", nil)
+	r.Header.Set("Accept", "text/event-stream")
+
+	h.ServeHTTP(w, r)
+
+	require.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	require.Equal(t, "data: {\"result\":{\"name\":\"a.proto\"}}\n\n", w.Body.String())
+}
+
+func TestStreamHandlerServeHTTPTerminatesWithErrorFrame(t *testing.T) {
+	h := newStreamHandler(t, &Route{QueryInputName: fileDescriptorProtoName}, func(ctx context.Context, methodName string, req gogoproto.Message) (func() (gogoproto.Message, error), error) {
+		calls := 0
+		return func() (gogoproto.Message, error) {
+			calls++
+			if calls == 1 {
+				return &descriptorpb.FileDescriptorProto{Name: strPtr("a.proto")}, nil
+			}
+			return nil, errors.New("upstream closed")
+		}, nil
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/x", nil)
+
+	h.ServeHTTP(w, r)
+
+	lines := splitNonEmptyLines(w.Body.String())
+	require.Len(t, lines, 2)
+	require.Contains(t, lines[1], `"error":"upstream closed"`)
+}
+
+func TestStreamHandlerServeHTTPHonorsResponseBodySelector(t *testing.T) {
+	route := &Route{QueryInputName: fileDescriptorProtoName, ResponseBody: "options"}
+	h := newStreamHandler(t, route, func(ctx context.Context, methodName string, req gogoproto.Message) (func() (gogoproto.Message, error), error) {
+		return recvSequence(&descriptorpb.FileDescriptorProto{
+			Name:    strPtr("a.proto"),
+			Options: &descriptorpb.FileOptions{JavaPackage: strPtr("com.example")},
+		}), nil
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/x", nil)
+
+	h.ServeHTTP(w, r)
+
+	body := w.Body.String()
+	require.Contains(t, body, "com.example")
+	require.NotContains(t, body, "a.proto")
+}
+
+func TestStreamHandlerServeHTTPRejectsUnaryRoute(t *testing.T) {
+	rt := NewRouter()
+	rt.Register(&Route{Method: http.MethodGet, Pattern: "/x", QueryInputName: fileDescriptorProtoName})
+	h := NewStreamHandler(rt, func(ctx context.Context, methodName string, req gogoproto.Message) (func() (gogoproto.Message, error), error) {
+		t.Fatal("should not be invoked for a non-streaming route")
+		return nil, nil
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/x", nil)
+	h.ServeHTTP(w, r)
+
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// splitNonEmptyLines splits s on newlines and returns the non-empty lines; empty lines and any
+// text after the last newline are dropped.
+func splitNonEmptyLines(s string) []string {
+	var lines []string
+	start := 0
+	for i, r := range s {
+		if r == '\n' {
+			if line := s[start:i]; line != "" {
+				lines = append(lines, line)
+			}
+			start = i + 1
+		}
+	}
+	return lines
+}
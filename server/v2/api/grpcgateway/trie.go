@@ -0,0 +1,138 @@
+package grpcgateway
+
+import (
+	"regexp"
+	"strings"
+)
+
+// tokenKind identifies the kind of path segment a token represents.
+type tokenKind int
+
+const (
+	// tokenLiteral matches a single path segment verbatim.
+	tokenLiteral tokenKind = iota
+	// tokenWildcard matches exactly one path segment, e.g. "{name}".
+	tokenWildcard
+	// tokenCatchAll matches the remainder of the path, e.g. "{name=**}".
+	tokenCatchAll
+)
+
+// token is a single compiled segment of a registered pattern.
+type token struct {
+	kind    tokenKind
+	literal string // set when kind == tokenLiteral
+	name    string // set when kind == tokenWildcard or tokenCatchAll
+}
+
+var paramSegment = regexp.MustCompile(`^\{([^=}]+)(=(.*))?\}$`)
+
+// tokenizePattern splits a google.api.http style pattern into a sequence of literal,
+// wildcard ("{name}") and catch-all ("{name=**}") tokens.
+func tokenizePattern(pattern string) []token {
+	pattern = strings.Trim(pattern, "/")
+	if pattern == "" {
+		return nil
+	}
+
+	segments := strings.Split(pattern, "/")
+	tokens := make([]token, 0, len(segments))
+
+	for _, segment := range segments {
+		m := paramSegment.FindStringSubmatch(segment)
+		if m == nil {
+			tokens = append(tokens, token{kind: tokenLiteral, literal: segment})
+			continue
+		}
+
+		name, spec := m[1], m[3]
+		if spec == "**" {
+			tokens = append(tokens, token{kind: tokenCatchAll, name: name})
+		} else {
+			tokens = append(tokens, token{kind: tokenWildcard, name: name})
+		}
+	}
+
+	return tokens
+}
+
+// trieNode is one level of the per-method route trie. Literal children are preferred over
+// the single wildcard child, which is preferred over the catch-all child, matching
+// grpc-gateway pattern semantics.
+type trieNode struct {
+	literal map[string]*trieNode
+
+	wildcard     *trieNode
+	wildcardName string
+
+	catchAll     *trieNode
+	catchAllName string
+
+	// route is set on the node that terminates a registered pattern.
+	route *Route
+}
+
+func newTrieNode() *trieNode {
+	return &trieNode{literal: make(map[string]*trieNode)}
+}
+
+// insert walks (creating as needed) the path described by tokens and attaches route to the
+// terminal node.
+func (n *trieNode) insert(tokens []token, route *Route) {
+	node := n
+	for _, tok := range tokens {
+		switch tok.kind {
+		case tokenLiteral:
+			child, ok := node.literal[tok.literal]
+			if !ok {
+				child = newTrieNode()
+				node.literal[tok.literal] = child
+			}
+			node = child
+		case tokenWildcard:
+			if node.wildcard == nil {
+				node.wildcard = newTrieNode()
+				node.wildcardName = tok.name
+			}
+			node = node.wildcard
+		case tokenCatchAll:
+			if node.catchAll == nil {
+				node.catchAll = newTrieNode()
+				node.catchAllName = tok.name
+			}
+			node = node.catchAll
+		}
+	}
+	node.route = route
+}
+
+// match walks segments against the trie, preferring literal matches, then the single
+// wildcard, then the catch-all, backtracking when a branch turns out to be a dead end.
+// Wildcard captures are written into params as they are descended into.
+func (n *trieNode) match(segments []string, params map[string]string) *Route {
+	if len(segments) == 0 {
+		return n.route
+	}
+
+	segment, rest := segments[0], segments[1:]
+
+	if child, ok := n.literal[segment]; ok {
+		if route := child.match(rest, params); route != nil {
+			return route
+		}
+	}
+
+	if n.wildcard != nil {
+		params[n.wildcardName] = segment
+		if route := n.wildcard.match(rest, params); route != nil {
+			return route
+		}
+		delete(params, n.wildcardName)
+	}
+
+	if n.catchAll != nil {
+		params[n.catchAllName] = strings.Join(segments, "/")
+		return n.catchAll.route
+	}
+
+	return nil
+}
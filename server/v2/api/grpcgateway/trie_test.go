@@ -0,0 +1,82 @@
+package grpcgateway
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenizePattern(t *testing.T) {
+	tokens := tokenizePattern("/cosmos/bank/v1beta1/balances/{address}/by_denom/{denom=**}")
+	require.Equal(t, []token{
+		{kind: tokenLiteral, literal: "cosmos"},
+		{kind: tokenLiteral, literal: "bank"},
+		{kind: tokenLiteral, literal: "v1beta1"},
+		{kind: tokenLiteral, literal: "balances"},
+		{kind: tokenWildcard, name: "address"},
+		{kind: tokenLiteral, literal: "by_denom"},
+		{kind: tokenCatchAll, name: "denom"},
+	}, tokens)
+
+	require.Nil(t, tokenizePattern(""))
+	require.Nil(t, tokenizePattern("/"))
+}
+
+func TestTrieNodeMatchPrefersLiteralOverWildcard(t *testing.T) {
+	root := newTrieNode()
+	literalRoute := &Route{Pattern: "/a/b"}
+	wildcardRoute := &Route{Pattern: "/a/{x}"}
+	root.insert(tokenizePattern(literalRoute.Pattern), literalRoute)
+	root.insert(tokenizePattern(wildcardRoute.Pattern), wildcardRoute)
+
+	params := map[string]string{}
+	require.Same(t, literalRoute, root.match([]string{"a", "b"}, params))
+	require.Empty(t, params)
+
+	params = map[string]string{}
+	require.Same(t, wildcardRoute, root.match([]string{"a", "c"}, params))
+	require.Equal(t, "c", params["x"])
+}
+
+func TestTrieNodeMatchBacktracksFromDeadEndLiteral(t *testing.T) {
+	root := newTrieNode()
+	literalRoute := &Route{Pattern: "/a/b/qux"}
+	wildcardRoute := &Route{Pattern: "/a/{x}/foo"}
+	root.insert(tokenizePattern(literalRoute.Pattern), literalRoute)
+	root.insert(tokenizePattern(wildcardRoute.Pattern), wildcardRoute)
+
+	// "b" matches the literal child of "a", but that branch dead-ends at "foo" (it only
+	// knows "qux"), so match must backtrack and fall through to the wildcard branch.
+	params := map[string]string{}
+	route := root.match([]string{"a", "b", "foo"}, params)
+	require.Same(t, wildcardRoute, route)
+	require.Equal(t, "b", params["x"])
+}
+
+func TestTrieNodeMatchCleansUpParamsOnFailedBacktrack(t *testing.T) {
+	root := newTrieNode()
+	route := &Route{Pattern: "/a/{x}/known"}
+	root.insert(tokenizePattern(route.Pattern), route)
+
+	params := map[string]string{}
+	require.Nil(t, root.match([]string{"a", "b", "unknown"}, params))
+	require.Empty(t, params, "a wildcard capture must be discarded when its branch fails to match")
+}
+
+func TestTrieNodeMatchCatchAllConsumesRemainder(t *testing.T) {
+	root := newTrieNode()
+	route := &Route{Pattern: "/files/{path=**}"}
+	root.insert(tokenizePattern(route.Pattern), route)
+
+	params := map[string]string{}
+	require.Same(t, route, root.match([]string{"files", "a", "b", "c"}, params))
+	require.Equal(t, "a/b/c", params["path"])
+}
+
+func TestTrieNodeMatchNoRoute(t *testing.T) {
+	root := newTrieNode()
+	root.insert(tokenizePattern("/a/b"), &Route{Pattern: "/a/b"})
+
+	require.Nil(t, root.match([]string{"a", "c"}, map[string]string{}))
+	require.Nil(t, root.match(nil, map[string]string{}))
+}
@@ -2,13 +2,9 @@ package grpcgateway
 
 import (
 	"fmt"
-	"io"
-	"net/http"
 	"reflect"
 	"regexp"
-	"strings"
 
-	"github.com/cosmos/gogoproto/jsonpb"
 	gogoproto "github.com/cosmos/gogoproto/proto"
 	"github.com/mitchellh/mapstructure"
 )
@@ -17,8 +13,8 @@ const MaxBodySize = 1 << 20 // 1 MB
 
 // URIMatch contains information related to a URI match.
 type URIMatch struct {
-	// QueryInputName is the fully qualified name of the proto input type of the query rpc method.
-	QueryInputName string
+	// Route is the matched route, describing the bound rpc method and its body/path semantics.
+	Route *Route
 
 	// Params are any wildcard params found in the request.
 	//
@@ -31,139 +27,109 @@ func (uri URIMatch) HasParams() bool {
 	return len(uri.Params) > 0
 }
 
-// matchURI attempts to find a match for the given URI.
-// NOTE: if no match is found, nil is returned.
-func matchURI(uri string, getPatternToQueryInputName map[string]string) *URIMatch {
-	uri = strings.TrimRight(uri, "/")
-
-	// for simple cases where there are no wildcards, we can just do a map lookup.
-	if inputName, ok := getPatternToQueryInputName[uri]; ok {
-		return &URIMatch{
-			QueryInputName: inputName,
-		}
+// newMessage creates a zero-valued instance of the proto message registered under queryInputName.
+func newMessage(queryInputName string) (gogoproto.Message, error) {
+	requestType := gogoproto.MessageType(queryInputName)
+	if requestType == nil {
+		return nil, fmt.Errorf("unknown request type")
 	}
 
-	// attempt to find a match in the pattern map.
-	for getPattern, queryInputName := range getPatternToQueryInputName {
-		getPattern = strings.TrimRight(getPattern, "/")
-
-		regexPattern, wildcardNames := patternToRegex(getPattern)
-
-		regex := regexp.MustCompile(regexPattern)
-		matches := regex.FindStringSubmatch(uri)
-
-		if matches != nil && len(matches) > 1 {
-			// first match is the full string, subsequent matches are capture groups
-			params := make(map[string]string)
-			for i, name := range wildcardNames {
-				params[name] = matches[i+1]
-			}
-
-			return &URIMatch{
-				QueryInputName: queryInputName,
-				Params:         params,
-			}
-		}
+	msg, ok := reflect.New(requestType.Elem()).Interface().(gogoproto.Message)
+	if !ok {
+		return nil, fmt.Errorf("failed to create message instance")
 	}
 
-	return nil
+	return msg, nil
 }
 
-// patternToRegex converts a URI pattern with wildcards to a regex pattern.
-// Returns the regex pattern and a slice of wildcard names in order
-func patternToRegex(pattern string) (string, []string) {
-	escaped := regexp.QuoteMeta(pattern)
-	var wildcardNames []string
-
-	// extract and replace {param=**} patterns
-	r1 := regexp.MustCompile(`\\\{([^}]+?)=\\\*\\\*\\}`)
-	escaped = r1.ReplaceAllStringFunc(escaped, func(match string) string {
-		// extract wildcard name without the =** suffix
-		name := regexp.MustCompile(`\\\{(.+?)=`).FindStringSubmatch(match)[1]
-		wildcardNames = append(wildcardNames, name)
-		return "(.+)"
-	})
-
-	// extract and replace {param} patterns
-	r2 := regexp.MustCompile(`\\\{([^}]+)\\}`)
-	escaped = r2.ReplaceAllStringFunc(escaped, func(match string) string {
-		// extract wildcard name from the curl braces {}.
-		name := regexp.MustCompile(`\\\{(.*?)\\}`).FindStringSubmatch(match)[1]
-		wildcardNames = append(wildcardNames, name)
-		return "([^/]+)"
-	})
+// bodyField returns the addressable proto.Message held by msg's field whose protobuf name
+// matches name, for use as the unmarshal target of a `body: "<field>"` selector.
+func bodyField(msg gogoproto.Message, name string) (gogoproto.Message, error) {
+	v := reflect.ValueOf(msg).Elem()
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("protobuf")
+		nameMatch := regexp.MustCompile(`name=(\w+)`).FindStringSubmatch(tag)
+		if len(nameMatch) < 2 || nameMatch[1] != name {
+			continue
+		}
 
-	return "^" + escaped + "$", wildcardNames
-}
+		fieldVal := v.Field(i)
+		if fieldVal.Kind() == reflect.Ptr && fieldVal.IsNil() {
+			fieldVal.Set(reflect.New(fieldVal.Type().Elem()))
+		}
 
-// createMessageFromJSON creates a message from the URIMatch given the JSON body in the http request.
-func createMessageFromJSON(match *URIMatch, r *http.Request) (gogoproto.Message, error) {
-	requestType := gogoproto.MessageType(match.QueryInputName)
-	if requestType == nil {
-		return nil, fmt.Errorf("unknown request type")
-	}
+		fieldMsg, ok := fieldVal.Interface().(gogoproto.Message)
+		if !ok {
+			return nil, fmt.Errorf("field %q is not a message type", name)
+		}
 
-	msg, ok := reflect.New(requestType.Elem()).Interface().(gogoproto.Message)
-	if !ok {
-		return nil, fmt.Errorf("failed to create message instance")
+		return fieldMsg, nil
 	}
 
-	defer r.Body.Close()
-	limitedReader := io.LimitReader(r.Body, MaxBodySize)
-	err := jsonpb.Unmarshal(limitedReader, msg)
-	if err != nil {
-		return nil, fmt.Errorf("error parsing body: %w", err)
-	}
+	return nil, fmt.Errorf("unknown body field %q", name)
+}
 
-	return msg, nil
+// responseField returns the proto.Message held by resp's field whose protobuf name matches
+// name, for use as the marshal source when a route's ResponseBody selector names a field.
+func responseField(resp gogoproto.Message, name string) (gogoproto.Message, error) {
+	v := reflect.ValueOf(resp).Elem()
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("protobuf")
+		nameMatch := regexp.MustCompile(`name=(\w+)`).FindStringSubmatch(tag)
+		if len(nameMatch) < 2 || nameMatch[1] != name {
+			continue
+		}
 
-}
+		fieldMsg, ok := v.Field(i).Interface().(gogoproto.Message)
+		if !ok {
+			return nil, fmt.Errorf("field %q is not a message type", name)
+		}
 
-// createMessage creates a message from the given URIMatch. If the match has params, the message will be populated
-// with the value of those params. Otherwise, an empty message is returned.
-func createMessage(match *URIMatch) (gogoproto.Message, error) {
-	requestType := gogoproto.MessageType(match.QueryInputName)
-	if requestType == nil {
-		return nil, fmt.Errorf("unknown request type")
+		return fieldMsg, nil
 	}
 
-	msg, ok := reflect.New(requestType.Elem()).Interface().(gogoproto.Message)
-	if !ok {
-		return nil, fmt.Errorf("failed to create message instance")
-	}
+	return nil, fmt.Errorf("unknown response field %q", name)
+}
 
-	// if the uri match has params, we need to populate the message with the values of those params.
-	if match.HasParams() {
-		// create a map with the proper field names from protobuf tags
-		fieldMap := make(map[string]string)
-		v := reflect.ValueOf(msg).Elem()
-		t := v.Type()
-
-		for key, value := range match.Params {
-			// attempt to match wildcard name to protobuf struct tag.
-			for i := 0; i < t.NumField(); i++ {
-				field := t.Field(i)
-				tag := field.Tag.Get("protobuf")
-				if nameMatch := regexp.MustCompile(`name=(\w+)`).FindStringSubmatch(tag); len(nameMatch) > 1 {
-					if nameMatch[1] == key {
-						fieldMap[field.Name] = value
-						break
-					}
+// populateFromParams fills msg's top-level fields from URI wildcard params, matching each
+// param name against the message's protobuf field tags.
+func populateFromParams(msg gogoproto.Message, params map[string]string) error {
+	// create a map with the proper field names from protobuf tags
+	fieldMap := make(map[string]string)
+	v := reflect.ValueOf(msg).Elem()
+	t := v.Type()
+
+	for key, value := range params {
+		// attempt to match wildcard name to protobuf struct tag.
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			tag := field.Tag.Get("protobuf")
+			if nameMatch := regexp.MustCompile(`name=(\w+)`).FindStringSubmatch(tag); len(nameMatch) > 1 {
+				if nameMatch[1] == key {
+					fieldMap[field.Name] = value
+					break
 				}
 			}
 		}
+	}
 
-		decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
-			Result:           msg,
-			WeaklyTypedInput: true, // TODO(technicallyty): should we put false here?
-		})
-		if err != nil {
-			return nil, fmt.Errorf("failed to create decoder: %w", err)
-		}
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:           msg,
+		WeaklyTypedInput: true, // TODO(technicallyty): should we put false here?
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create decoder: %w", err)
+	}
 
-		if err := decoder.Decode(fieldMap); err != nil {
-			return nil, fmt.Errorf("failed to decode params: %w", err)
-		}
+	if err := decoder.Decode(fieldMap); err != nil {
+		return fmt.Errorf("failed to decode params: %w", err)
 	}
-	return msg, nil
+
+	return nil
 }
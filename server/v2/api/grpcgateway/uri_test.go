@@ -0,0 +1,63 @@
+package grpcgateway
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fixtureCoin and fixtureMsg stand in for gogoproto-generated messages: plain structs with
+// protobuf struct tags, satisfying the minimal gogoproto.Message interface these helpers rely
+// on via reflection.
+type fixtureCoin struct {
+	Denom string `protobuf:"bytes,1,opt,name=denom"`
+}
+
+func (m *fixtureCoin) Reset()         { *m = fixtureCoin{} }
+func (m *fixtureCoin) String() string { return "" }
+func (m *fixtureCoin) ProtoMessage()  {}
+
+type fixtureMsg struct {
+	Name   string       `protobuf:"bytes,1,opt,name=name"`
+	Amount *fixtureCoin `protobuf:"bytes,2,opt,name=amount"`
+}
+
+func (m *fixtureMsg) Reset()         { *m = fixtureMsg{} }
+func (m *fixtureMsg) String() string { return "" }
+func (m *fixtureMsg) ProtoMessage()  {}
+
+func TestBodyField(t *testing.T) {
+	msg := &fixtureMsg{}
+
+	field, err := bodyField(msg, "amount")
+	require.NoError(t, err)
+	require.Same(t, msg.Amount, field, "bodyField must allocate a nil message field lazily")
+
+	_, err = bodyField(msg, "missing")
+	require.Error(t, err)
+
+	_, err = bodyField(msg, "name")
+	require.Error(t, err, "a scalar field is not a valid body target")
+}
+
+func TestPopulateFromParams(t *testing.T) {
+	msg := &fixtureMsg{}
+
+	err := populateFromParams(msg, map[string]string{"name": "alice"})
+	require.NoError(t, err)
+	require.Equal(t, "alice", msg.Name)
+}
+
+func TestResponseField(t *testing.T) {
+	msg := &fixtureMsg{Amount: &fixtureCoin{Denom: "uatom"}}
+
+	field, err := responseField(msg, "amount")
+	require.NoError(t, err)
+	require.Same(t, msg.Amount, field)
+
+	_, err = responseField(msg, "missing")
+	require.Error(t, err)
+
+	_, err = responseField(msg, "name")
+	require.Error(t, err, "a scalar field is not a valid response body selector target")
+}